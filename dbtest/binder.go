@@ -0,0 +1,180 @@
+package dbtest
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// namedParamPattern matches named SQL parameters in either `:name` or `$name`
+// form, including dotted field access such as `:user.email`. Positional
+// placeholders (`$1`, `$2`, ...) never match since a named parameter must
+// start with a letter or underscore. A leading `:` that's the second colon of
+// a Postgres type cast (`::jsonb`) is excluded separately in bindNamed, since
+// RE2 has no lookbehind to express that here.
+var namedParamPattern = regexp.MustCompile(`[:$]([A-Za-z_][A-Za-z0-9_]*(?:\.[A-Za-z_][A-Za-z0-9_]*)*)`)
+
+// bindNamed rewrites the named parameters in stmt to the driver's positional
+// placeholders, built by calling ph with the 1-based index of each bound
+// argument, and returns the rewritten statement along with the ordered
+// argument slice built from params. A parameter bound to a slice (other than
+// []byte, which is treated as a scalar) is expanded into one placeholder per
+// element, which supports `IN (:ids)`-style clauses. A `::type` cast, e.g.
+// `'{}'::jsonb`, is left untouched rather than treated as a named parameter.
+func bindNamed(stmt string, params map[string]interface{}, ph func(i int) string) (string, []interface{}, error) {
+	quoted := quotedRanges(stmt)
+
+	var args []interface{}
+	var out strings.Builder
+	last := 0
+	for _, loc := range namedParamPattern.FindAllStringSubmatchIndex(stmt, -1) {
+		if stmt[loc[0]] == ':' && loc[0] > 0 && stmt[loc[0]-1] == ':' {
+			// Second colon of a '::type' cast, not a named parameter.
+			continue
+		}
+		if inQuotedRange(quoted, loc[0]) {
+			// Inside a '...' or "..." literal, e.g. 'error:invalid_input'.
+			continue
+		}
+		out.WriteString(stmt[last:loc[0]])
+		name := stmt[loc[2]:loc[3]]
+		val, err := resolveParam(params, name)
+		if err != nil {
+			return "", nil, err
+		}
+		rv := reflect.ValueOf(val)
+		if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() != reflect.Uint8 {
+			if rv.Len() == 0 {
+				return "", nil, fmt.Errorf("parameter '%s' is an empty slice", name)
+			}
+			placeholders := make([]string, rv.Len())
+			for i := 0; i < rv.Len(); i++ {
+				args = append(args, rv.Index(i).Interface())
+				placeholders[i] = ph(len(args))
+			}
+			out.WriteString(strings.Join(placeholders, ", "))
+		} else {
+			args = append(args, val)
+			out.WriteString(ph(len(args)))
+		}
+		last = loc[1]
+	}
+	out.WriteString(stmt[last:])
+	return out.String(), args, nil
+}
+
+// quotedRanges returns the half-open byte ranges of stmt that lie inside
+// single- or double-quoted string literals, with a doubled quote character
+// ('' or "") treated as an escaped literal quote rather than the literal's
+// end, mirroring standard SQL string-literal escaping. bindNamed uses this
+// to avoid mistaking a `:`/`$` inside a literal, e.g. 'error:invalid_input',
+// for a named parameter.
+func quotedRanges(stmt string) [][2]int {
+	var ranges [][2]int
+	var quote byte
+	start := 0
+	for i := 0; i < len(stmt); i++ {
+		c := stmt[i]
+		if quote == 0 {
+			if c == '\'' || c == '"' {
+				quote = c
+				start = i
+			}
+			continue
+		}
+		if c != quote {
+			continue
+		}
+		if i+1 < len(stmt) && stmt[i+1] == quote {
+			i++
+			continue
+		}
+		ranges = append(ranges, [2]int{start, i + 1})
+		quote = 0
+	}
+	if quote != 0 {
+		ranges = append(ranges, [2]int{start, len(stmt)})
+	}
+	return ranges
+}
+
+// inQuotedRange reports whether pos falls inside one of ranges.
+func inQuotedRange(ranges [][2]int, pos int) bool {
+	for _, r := range ranges {
+		if pos < r[0] {
+			break
+		}
+		if pos < r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveParam looks up name in params, descending into struct fields for
+// dotted names (e.g. "user.email" looks up "user" and then its Email field).
+func resolveParam(params map[string]interface{}, name string) (interface{}, error) {
+	comps := strings.Split(name, ".")
+	val, ok := params[comps[0]]
+	if !ok {
+		return nil, fmt.Errorf("unknown parameter '%s'", comps[0])
+	}
+	for _, c := range comps[1:] {
+		rv := reflect.ValueOf(val)
+		for rv.Kind() == reflect.Pointer {
+			rv = rv.Elem()
+		}
+		if rv.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("cannot access field '%s' of parameter '%s', not a struct", c, comps[0])
+		}
+		f := rv.FieldByName(strings.ToUpper(c[:1]) + c[1:])
+		if !f.IsValid() {
+			return nil, fmt.Errorf("field '%s' not found in parameter '%s'", c, comps[0])
+		}
+		val = f.Interface()
+	}
+	return val, nil
+}
+
+// objectParams builds obj into a struct (as BuildObject would) and returns
+// its exported fields keyed by their lower-camel-case name, e.g. a field
+// named Email becomes the parameter "email".
+func objectParams(obj *Object) (map[string]interface{}, error) {
+	built, err := BuildObject(obj)
+	if err != nil {
+		return nil, err
+	}
+	rv := reflect.ValueOf(built)
+	for rv.Kind() == reflect.Pointer {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("params object of type '%s' must build to a struct", obj.Type)
+	}
+	params := make(map[string]interface{}, rv.NumField())
+	t := rv.Type()
+	for i := 0; i < rv.NumField(); i++ {
+		name := t.Field(i).Name
+		key := strings.ToLower(name[:1]) + name[1:]
+		params[key] = rv.Field(i).Interface()
+	}
+	return params, nil
+}
+
+// argumentParams returns the named entries of args (those with a non-empty
+// Name) keyed by that name, so an Assert.Query can bind against the values
+// already built for the test's Act.Arguments. built must be the slice
+// BuildObject produced for args, in the same order, rather than rebuilt
+// values: rebuilding would call a codec like "time"'s relative "now" a
+// second time, binding a different value than the one actually passed to Act.
+func argumentParams(args []Object, built []interface{}) map[string]interface{} {
+	params := make(map[string]interface{})
+	for i, obj := range args {
+		if obj.Name == "" {
+			continue
+		}
+		params[obj.Name] = built[i]
+	}
+	return params
+}
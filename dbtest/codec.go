@@ -0,0 +1,346 @@
+package dbtest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Codec lets an Object whose Type has no other meaning to BuildObject be
+// built from its raw YAML value and compared against a value scanned from
+// the database or returned from a method call.
+type Codec interface {
+	// Build constructs a Go value from the Object's raw YAML value.
+	Build(value interface{}) (interface{}, error)
+	// Compare reports whether actual matches a value built by Build.
+	Compare(expected, actual interface{}) (bool, error)
+}
+
+var codecs = make(map[string]Codec)
+
+// RegisterCodec makes a Codec available to Objects whose Type is name.
+func RegisterCodec(name string, c Codec) {
+	codecs[name] = c
+}
+
+func init() {
+	RegisterCodec("time", timeCodec{})
+	RegisterCodec("uuid", uuidCodec{})
+	RegisterCodec("decimal", decimalCodec{})
+	RegisterCodec("bytes", bytesCodec{})
+	RegisterCodec("bool", boolCodec{})
+	RegisterCodec("float", floatCodec{})
+	RegisterCodec("json", jsonCodec{})
+}
+
+// valueEquals reports whether actual matches expected, routing through
+// obj.Type's registered Codec if one exists, otherwise falling back to
+// plain reflect.DeepEqual (the behavior before codecs existed).
+func valueEquals(obj *Object, expected, actual interface{}) (bool, error) {
+	c, ok := codecs[obj.Type]
+	if !ok {
+		return reflect.DeepEqual(expected, actual), nil
+	}
+	if v := reflect.ValueOf(actual); v.Kind() == reflect.Pointer {
+		actual = v.Elem().Interface()
+	}
+	return c.Compare(expected, actual)
+}
+
+// compareObject builds obj and compares it against actual (a value scanned
+// from a database row), routing through obj.Type's registered Codec if one
+// exists, otherwise falling back to compareValue's type-converted
+// reflect.DeepEqual. It returns a *TestError describing any mismatch.
+func compareObject(testName, fieldName string, obj *Object, actual interface{}) error {
+	expected, err := BuildObject(obj)
+	if err != nil {
+		return err
+	}
+	if _, ok := codecs[obj.Type]; !ok {
+		return compareValue(testName, fieldName, expected, actual)
+	}
+	eq, err := valueEquals(obj, expected, actual)
+	if err != nil {
+		return err
+	}
+	if !eq {
+		return &TestError{name: testName, message: fmt.Sprintf("values of field '%s' not equal: '%v' /= '%v'", fieldName, expected, actual)}
+	}
+	return nil
+}
+
+// timeCodec builds and compares "time" Objects: an RFC3339 timestamp, or a
+// relative expression such as "now" or "now-1h" resolved at Build time.
+// Compare allows up to timeTolerance of drift, since "now"-based fixtures
+// can't match a stored timestamp exactly.
+type timeCodec struct{}
+
+const timeTolerance = time.Second
+
+var relativeTimePattern = regexp.MustCompile(`^now([+-]\d+(?:ns|us|µs|ms|s|m|h))?$`)
+
+func (timeCodec) Build(value interface{}) (interface{}, error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, errors.New("type 'time' expects value of type 'string'")
+	}
+	if m := relativeTimePattern.FindStringSubmatch(s); m != nil {
+		if m[1] == "" {
+			return time.Now(), nil
+		}
+		d, err := time.ParseDuration(m[1])
+		if err != nil {
+			return nil, fmt.Errorf("type 'time', invalid relative expression '%s' (%w)", s, err)
+		}
+		return time.Now().Add(d), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil, fmt.Errorf("type 'time', '%s' isn't RFC3339 or a relative expression (%w)", s, err)
+	}
+	return t, nil
+}
+
+func (timeCodec) Compare(expected, actual interface{}) (bool, error) {
+	e, ok := expected.(time.Time)
+	if !ok {
+		return false, fmt.Errorf("expected value of type 'time' isn't a time.Time (is %T)", expected)
+	}
+	a, ok := actual.(time.Time)
+	if !ok {
+		return false, fmt.Errorf("actual value isn't a time.Time for type 'time' (is %T)", actual)
+	}
+	diff := e.Sub(a)
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff <= timeTolerance, nil
+}
+
+// uuidCodec builds and compares "uuid" Objects, matching case-insensitively
+// since UUIDs are conventionally rendered in either case.
+type uuidCodec struct{}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+func (uuidCodec) Build(value interface{}) (interface{}, error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, errors.New("type 'uuid' expects value of type 'string'")
+	}
+	if !uuidPattern.MatchString(s) {
+		return nil, fmt.Errorf("type 'uuid', '%s' isn't a valid UUID", s)
+	}
+	return strings.ToLower(s), nil
+}
+
+func (uuidCodec) Compare(expected, actual interface{}) (bool, error) {
+	e, ok := expected.(string)
+	if !ok {
+		return false, fmt.Errorf("expected value of type 'uuid' isn't a string (is %T)", expected)
+	}
+	return strings.EqualFold(e, fmt.Sprintf("%v", actual)), nil
+}
+
+// Decimal is a minimal arbitrary-precision decimal value built by the
+// "decimal" codec, which compares numerically rather than by string
+// representation, so "1.50" and "1.5" are considered equal.
+type Decimal struct {
+	rat *big.Rat
+}
+
+// String renders d in base-10, e.g. "3/2" becomes "1.5". A value with no
+// finite base-10 form, such as "1/3", renders as a fraction.
+func (d Decimal) String() string {
+	if prec, exact := d.rat.FloatPrec(); exact {
+		return d.rat.FloatString(prec)
+	}
+	return d.rat.RatString()
+}
+
+type decimalCodec struct{}
+
+func (decimalCodec) Build(value interface{}) (interface{}, error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, errors.New("type 'decimal' expects value of type 'string'")
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, fmt.Errorf("type 'decimal', '%s' isn't a valid decimal", s)
+	}
+	return Decimal{rat: r}, nil
+}
+
+// Compare accepts an actual value that is either a string or anything
+// implementing fmt.Stringer rendering a base-10 number (shopspring/decimal's
+// Decimal type satisfies this without dbtest depending on that package).
+func (decimalCodec) Compare(expected, actual interface{}) (bool, error) {
+	e, ok := expected.(Decimal)
+	if !ok {
+		return false, fmt.Errorf("expected value of type 'decimal' isn't a Decimal (is %T)", expected)
+	}
+	var s string
+	switch v := actual.(type) {
+	case string:
+		s = v
+	case fmt.Stringer:
+		s = v.String()
+	default:
+		return false, fmt.Errorf("actual value of unsupported type %T for 'decimal'", actual)
+	}
+	a, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return false, fmt.Errorf("type 'decimal', actual value '%s' isn't a valid decimal", s)
+	}
+	return e.rat.Cmp(a) == 0, nil
+}
+
+// bytesCodec builds and compares "bytes" Objects, given either a plain
+// base64 string or a map with a "base64" or "hex" key.
+type bytesCodec struct{}
+
+func (bytesCodec) Build(value interface{}) (interface{}, error) {
+	if m, ok := value.(map[string]interface{}); ok {
+		if s, ok := m["base64"].(string); ok {
+			return base64.StdEncoding.DecodeString(s)
+		}
+		if s, ok := m["hex"].(string); ok {
+			return hex.DecodeString(s)
+		}
+		return nil, errors.New("type 'bytes' expects a 'base64' or 'hex' key")
+	}
+	s, ok := value.(string)
+	if !ok {
+		return nil, errors.New("type 'bytes' expects value of type 'string' or a map with 'base64'/'hex'")
+	}
+	return base64.StdEncoding.DecodeString(s)
+}
+
+func (bytesCodec) Compare(expected, actual interface{}) (bool, error) {
+	e, ok := expected.([]byte)
+	if !ok {
+		return false, fmt.Errorf("expected value of type 'bytes' isn't []byte (is %T)", expected)
+	}
+	switch a := actual.(type) {
+	case []byte:
+		return bytes.Equal(e, a), nil
+	case string:
+		return bytes.Equal(e, []byte(a)), nil
+	default:
+		return false, fmt.Errorf("actual value of unsupported type %T for 'bytes'", actual)
+	}
+}
+
+// boolCodec builds and compares "bool" Objects.
+type boolCodec struct{}
+
+func (boolCodec) Build(value interface{}) (interface{}, error) {
+	b, ok := value.(bool)
+	if !ok {
+		return nil, errors.New("type 'bool' expects value of type 'bool'")
+	}
+	return b, nil
+}
+
+func (boolCodec) Compare(expected, actual interface{}) (bool, error) {
+	e, ok := expected.(bool)
+	if !ok {
+		return false, fmt.Errorf("expected value of type 'bool' isn't a bool (is %T)", expected)
+	}
+	a, ok := actual.(bool)
+	if !ok {
+		return false, fmt.Errorf("actual value of unsupported type %T for 'bool'", actual)
+	}
+	return e == a, nil
+}
+
+// floatCodec builds and compares "float" Objects, allowing up to
+// floatTolerance of drift to absorb floating-point rounding.
+type floatCodec struct{}
+
+const floatTolerance = 1e-9
+
+func (floatCodec) Build(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case int:
+		return float64(v), nil
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("type 'float' expects a numeric value (%w)", err)
+		}
+		return f, nil
+	default:
+		return nil, fmt.Errorf("type 'float' expects a numeric or string value (is %T)", value)
+	}
+}
+
+func (floatCodec) Compare(expected, actual interface{}) (bool, error) {
+	e, ok := expected.(float64)
+	if !ok {
+		return false, fmt.Errorf("expected value of type 'float' isn't a float64 (is %T)", expected)
+	}
+	v := reflect.ValueOf(actual)
+	var a float64
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		a = v.Float()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		a = float64(v.Int())
+	default:
+		return false, fmt.Errorf("actual value of unsupported type %T for 'float'", actual)
+	}
+	diff := e - a
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff <= floatTolerance, nil
+}
+
+// jsonCodec builds and compares "json" Objects structurally (unmarshaling
+// into generic maps/slices), so key order and driver representation
+// (string vs []byte) don't affect the comparison. To unmarshal into a
+// registered struct type instead, use Object.Type "json:<type>" (e.g.
+// "json:dbtest.User"), which BuildObject handles directly.
+type jsonCodec struct{}
+
+func (jsonCodec) Build(value interface{}) (interface{}, error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, errors.New("type 'json' expects value of type 'JSON string'")
+	}
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return nil, fmt.Errorf("type 'json', failed to unmarshal (%w)", err)
+	}
+	return v, nil
+}
+
+func (jsonCodec) Compare(expected, actual interface{}) (bool, error) {
+	var raw []byte
+	switch a := actual.(type) {
+	case []byte:
+		raw = a
+	case string:
+		raw = []byte(a)
+	default:
+		return false, fmt.Errorf("actual value of unsupported type %T for 'json'", actual)
+	}
+	var a interface{}
+	if err := json.Unmarshal(raw, &a); err != nil {
+		return false, fmt.Errorf("type 'json', failed to unmarshal actual value (%w)", err)
+	}
+	return reflect.DeepEqual(expected, a), nil
+}
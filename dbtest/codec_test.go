@@ -0,0 +1,42 @@
+package dbtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeCodec(t *testing.T) {
+	a := assert.New(t)
+
+	built, err := timeCodec{}.Build("now-1h")
+	a.Nil(err)
+	expected := built.(time.Time)
+
+	eq, err := timeCodec{}.Compare(expected, time.Now().Add(-time.Hour))
+	a.Nil(err)
+	a.True(eq)
+}
+
+func TestDecimalCodec(t *testing.T) {
+	a := assert.New(t)
+
+	built, err := decimalCodec{}.Build("1.50")
+	a.Nil(err)
+
+	eq, err := decimalCodec{}.Compare(built, "1.5")
+	a.Nil(err)
+	a.True(eq)
+}
+
+func TestJSONCodec(t *testing.T) {
+	a := assert.New(t)
+
+	built, err := jsonCodec{}.Build(`{"a": 1, "b": 2}`)
+	a.Nil(err)
+
+	eq, err := jsonCodec{}.Compare(built, []byte(`{"b": 2, "a": 1}`))
+	a.Nil(err)
+	a.True(eq)
+}
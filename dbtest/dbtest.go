@@ -9,14 +9,17 @@ import (
 	"io"
 	"log"
 	"reflect"
+	"strconv"
 	"strings"
 
-	_ "github.com/lib/pq" // Postgres driver
 	"gopkg.in/yaml.v3"
 )
 
 // TestSet holds an array of DBTest. This maps to the top-level 'tests' array in the YAML.
 type TestSet struct {
+	// Driver selects the backend to run against by name (e.g. "postgres",
+	// "mysql", "sqlite3"). Defaults to "postgres" if empty.
+	Driver string `yaml:"driver"`
 	// Tests is an array of DBTest objects, each representing a database test to run.
 	Tests []DBTest `yaml:"tests"`
 }
@@ -31,12 +34,25 @@ type DBTest struct {
 	Act Act `yaml:"act"`
 	// Assert holds the information needed to verify the state of the database after the test.
 	Assert []Assert `yaml:"assert"`
+	// Isolate controls whether the test runs inside a BEGIN/ROLLBACK
+	// transaction so its Arrange mutations never leak into later tests.
+	// Defaults to false: Act calls the service under test through its own,
+	// separately wired connection to the database, which can't see
+	// mutations made inside an open transaction on another connection, so
+	// isolating Arrange would hide its writes from Act. Set to true only
+	// for tests whose Act doesn't need to observe Arrange's writes (e.g. it
+	// only exercises Assert.Query against the same connection).
+	Isolate *bool `yaml:"isolate"`
 }
 
 // Arrange encapsulates a single SQL statement used to prepare the database for a test.
 type Arrange struct {
-	// Statement is the SQL command to be executed.
+	// Statement is the SQL command to be executed. It may contain named
+	// parameters (`:name`, `$name` or `$name.field`) that are bound from Params.
 	Statement string `yaml:"statement"`
+	// Params, if set, is built into a struct whose fields supply the values
+	// for Statement's named parameters.
+	Params *Object `yaml:"params"`
 }
 
 // Act describes the method to call and its arguments.
@@ -50,23 +66,67 @@ type Act struct {
 // Assert contains information for making an assertion on the database state.
 type Assert struct {
 	// Value is used in some cases to assert that a method returns this value.
+	// For methods with more than one non-error return value, use Values instead.
 	Value Object `yaml:"value"`
-	// Query is the SQL query used to fetch data for assertion.
+	// Values, if non-empty, asserts each non-error return value of the
+	// method individually, in order, instead of only the first (Value).
+	Values []Object `yaml:"values"`
+	// Query is the SQL query used to fetch data for assertion. Like
+	// Arrange.Statement, it may contain named parameters, bound from Params
+	// and from the named entries of the test's Act.Arguments.
 	Query string `yaml:"query"`
+	// Params, if set, is built into a struct whose fields supply additional
+	// values for Query's named parameters.
+	Params *Object `yaml:"params"`
 	// Rows describe the expected rows returned by the Query.
 	Rows []Row `yaml:"rows"`
+	// Stream, if set, asserts Query's result set in batches against expected
+	// rows read from an external file instead of materializing Rows, for
+	// result sets too large to hold in memory.
+	Stream *Stream `yaml:"stream"`
+	// As, if set, names a registered struct type (e.g. "dbtest.User"). Instead
+	// of comparing Rows, Query's result set is scanned row-by-row into that
+	// type (matching columns to fields via `db` tags or the configured
+	// NameMapperFunc) and the resulting slice is compared against Value,
+	// which must build to a slice of the same type.
+	As string `yaml:"as"`
 	// Error holds an expected error message, if applicable.
 	Error string `yaml:"error"`
 }
 
 // Row encapsulates the expected columns for a row returned by a SQL query in an assertion.
 type Row struct {
-	// Columns is an array of Objects, each representing a column in a database row.
-	Columns []Object `yaml:"columns"`
+	// Columns is an array of Objects matching the SQL SELECT order positionally.
+	Columns []Object
+	// ColumnsByName maps a column name to its expected Object, matched
+	// against rows.Columns() by name instead of position.
+	ColumnsByName map[string]Object
+}
+
+// UnmarshalYAML lets a Row's 'columns' key be either a YAML sequence, matched
+// against the result set positionally, or a YAML mapping, matched by column name.
+func (row *Row) UnmarshalYAML(value *yaml.Node) error {
+	var wrapper struct {
+		Columns yaml.Node `yaml:"columns"`
+	}
+	if err := value.Decode(&wrapper); err != nil {
+		return err
+	}
+	switch wrapper.Columns.Kind {
+	case yaml.SequenceNode:
+		return wrapper.Columns.Decode(&row.Columns)
+	case yaml.MappingNode:
+		return wrapper.Columns.Decode(&row.ColumnsByName)
+	default:
+		return errors.New("'columns' must be a sequence or a mapping")
+	}
 }
 
 // Object represents a generic value with a type.
 type Object struct {
+	// Name, if set, lets an Act.Arguments entry be referenced by name from a
+	// named parameter in an Assert.Query (e.g. "email" for ":email").
+	Name string `yaml:"name"`
 	// Type describes the type of the Object (e.g., "int", "string", "customStruct").
 	Type string `yaml:"type"`
 	// Value holds the actual value of the Object.
@@ -219,6 +279,25 @@ func BuildObject(obj *Object) (interface{}, error) {
 		if obj.Type == "" {
 			return nil, errors.New("object type mustn't be empty")
 		}
+		if strings.HasPrefix(obj.Type, "json:") {
+			typeName := strings.TrimPrefix(obj.Type, "json:")
+			t, ok := customTypes[typeName]
+			if !ok {
+				return nil, fmt.Errorf("unknown custom type '%s' in 'json:' codec", typeName)
+			}
+			val, ok := obj.Value.(string)
+			if !ok {
+				return nil, fmt.Errorf("type '%s' expects value of type 'JSON string'", obj.Type)
+			}
+			var m map[string]interface{}
+			if err := json.Unmarshal([]byte(val), &m); err != nil {
+				return nil, fmt.Errorf("type '%s', failed to unmarshal JSON (%s)", obj.Type, err)
+			}
+			return buildObjectFromJSON(t, m)
+		}
+		if c, ok := codecs[obj.Type]; ok {
+			return c.Build(obj.Value)
+		}
 		comps := strings.Split(obj.Type, ".")
 		typeName := comps[len(comps)-1]
 		if typeName[:1] != strings.ToUpper(typeName[:1]) {
@@ -310,10 +389,19 @@ func buildValueFromJSON(typ reflect.Type, v interface{}) (interface{}, error) {
 
 // Run executes all the tests in a TestSet.
 // It takes a context, a database DSN (Data Source Name), and a service interface containing the methods to be tested.
+// The backend is selected by the TestSet's Driver field, defaulting to "postgres".
 func (ts *TestSet) Run(ctx context.Context, dbDsn string, service interface{}) error {
+	name := ts.Driver
+	if name == "" {
+		name = "postgres"
+	}
+	d, ok := drivers[name]
+	if !ok {
+		return fmt.Errorf("unknown driver '%s'", name)
+	}
 
 	// Open a new database connection.
-	db, err := sql.Open("postgres", dbDsn)
+	db, err := sql.Open(d.DriverName(), dbDsn)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -331,15 +419,29 @@ func (ts *TestSet) Run(ctx context.Context, dbDsn string, service interface{}) e
 		log.Fatal(err)
 	}
 
+	return ts.RunWithDB(ctx, db, d, service)
+}
+
+// RunWithDB runs the TestSet against an already-opened *sql.DB using the
+// given Driver. Use this entry point when the caller manages the database
+// connection itself, e.g. to run the same TestSet against several engines in
+// CI without reopening a connection per engine.
+func (ts *TestSet) RunWithDB(ctx context.Context, db *sql.DB, d Driver, service interface{}) error {
 	// Register the types in the service for use in reflection-based operations.
 	if err := registerServiceTypes(service); err != nil {
 		return err
 	}
 
+	if r, ok := d.(SchemaResetter); ok {
+		if err := r.ResetSchema(ctx, db); err != nil {
+			return err
+		}
+	}
+
 	// Iterate through each test in the TestSet and run it.
 	for _, t := range ts.Tests {
 		// Run each test and return an error if any test fails.
-		if err := t.Run(ctx, db, service); err != nil {
+		if err := t.Run(ctx, db, d, service); err != nil {
 			return err
 		}
 	}
@@ -347,34 +449,99 @@ func (ts *TestSet) Run(ctx context.Context, dbDsn string, service interface{}) e
 	return nil
 }
 
+// dbExecutor is satisfied by both *sql.DB and *sql.Tx, letting DBTest.Run
+// execute against either a plain connection or an isolating transaction.
+type dbExecutor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// scanRowValues scans the current row of rows into n values, letting
+// database/sql assign whatever concrete type the driver returned for each
+// column (int64, string, bool, []byte, time.Time, nil, ...) instead of
+// allocating scan destinations from ColumnType.ScanType(). Several drivers
+// (e.g. mattn/go-sqlite3, go-sql-driver/mysql) report ScanType() as a
+// generic sql.Null* wrapper for a column regardless of its actual
+// nullability, which would otherwise break straightforward comparisons
+// against a freshly-built expected value.
+func scanRowValues(rows *sql.Rows, n int) ([]interface{}, error) {
+	row := make([]interface{}, n)
+	dest := make([]interface{}, n)
+	for i := range dest {
+		dest[i] = &row[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return nil, err
+	}
+	return row, nil
+}
+
 // Run executes a DBTest. This function uses reflection to call methods dynamically and run SQL statements.
-func (t *DBTest) Run(ctx context.Context, db *sql.DB, service interface{}) error {
+func (t *DBTest) Run(ctx context.Context, db *sql.DB, d Driver, service interface{}) error {
+	isolate := false
+	if t.Isolate != nil {
+		isolate = *t.Isolate
+	}
+
+	var exec dbExecutor = db
+	if isolate {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+				log.Fatal(err)
+			}
+		}()
+		exec = tx
+	}
 	for _, arr := range t.Arrange {
-		if _, err := db.ExecContext(ctx, arr.Statement); err != nil {
+		params := make(map[string]interface{})
+		if arr.Params != nil {
+			p, err := objectParams(arr.Params)
+			if err != nil {
+				return err
+			}
+			params = p
+		}
+		stmt, args, err := bindNamed(arr.Statement, params, d.Placeholder)
+		if err != nil {
+			return err
+		}
+		if _, err := exec.ExecContext(ctx, stmt, args...); err != nil {
 			return err
 		}
 	}
 
 	s := reflect.ValueOf(service)
-	m, ok := s.Type().MethodByName(t.Act.Method)
-	if !ok {
-		return fmt.Errorf("method '%s' not found in service", t.Act.Method)
-	}
-
-	if m.Type.NumIn() != len(t.Act.Arguments)+1 {
-		return fmt.Errorf("invalid number of arguments to method '%s'", t.Act.Method)
-	}
-
-	args := make([]reflect.Value, len(t.Act.Arguments)+1)
-	args[0] = s
+	built := make([]interface{}, len(t.Act.Arguments))
+	argTypes := make([]reflect.Type, len(t.Act.Arguments))
 	for i, obj := range t.Act.Arguments {
-		obj, err := BuildObject(&obj)
+		v, err := BuildObject(&obj)
 		if err != nil {
 			return err
 		}
-		args[i+1] = reflect.ValueOf(obj)
+		built[i] = v
+		argTypes[i] = reflect.TypeOf(v)
+	}
+
+	m, resolveErr := resolveMethod(s.Type(), t.Act.Method, argTypes)
+	if resolveErr != nil {
+		return resolveErr
+	}
+
+	args, callSlice, buildErr := buildCallArgs(m, s, built)
+	if buildErr != nil {
+		return buildErr
+	}
+
+	var r []reflect.Value
+	if callSlice {
+		r = m.Func.CallSlice(args)
+	} else {
+		r = m.Func.Call(args)
 	}
-	r := m.Func.Call(args)
 	err := r[m.Type.NumOut()-1].Interface()
 
 	for _, ass := range t.Assert {
@@ -403,7 +570,21 @@ func (t *DBTest) Run(ctx context.Context, db *sql.DB, service interface{}) error
 				}
 
 			case ass.Query != "":
-				rows, err := db.QueryContext(ctx, ass.Query)
+				params := argumentParams(t.Act.Arguments, built)
+				if ass.Params != nil {
+					p, err := objectParams(ass.Params)
+					if err != nil {
+						return err
+					}
+					for k, v := range p {
+						params[k] = v
+					}
+				}
+				query, queryArgs, err := bindNamed(ass.Query, params, d.Placeholder)
+				if err != nil {
+					return err
+				}
+				rows, err := exec.QueryContext(ctx, query, queryArgs...)
 				if err != nil {
 					return err
 				}
@@ -413,6 +594,15 @@ func (t *DBTest) Run(ctx context.Context, db *sql.DB, service interface{}) error
 						log.Fatal(err)
 					}
 				}()
+
+				if ass.Stream != nil {
+					return assertStream(t.Name, rows, ass.Stream)
+				}
+
+				if ass.As != "" {
+					return assertResultSet(t.Name, rows, ass)
+				}
+
 				var i int
 				for rows.Next() {
 					if i >= len(ass.Rows) {
@@ -424,38 +614,24 @@ func (t *DBTest) Run(ctx context.Context, db *sql.DB, service interface{}) error
 					if err != nil {
 						return err
 					}
-					if len(row.Columns) != len(cols) {
-						return &TestError{name: t.Name, message: "invalid number of columns"}
-					}
-					expected := make([]interface{}, len(row.Columns))
-					actual := make([]interface{}, len(row.Columns))
-					for i, col := range cols {
-						expected[i], err = BuildObject(&row.Columns[i])
-						if err != nil {
+
+					if row.ColumnsByName != nil {
+						if err := assertRowByName(t.Name, row, cols, rows); err != nil {
 							return err
 						}
-						actual[i] = reflect.New(col.ScanType()).Interface()
+						continue
+					}
+
+					if len(row.Columns) != len(cols) {
+						return &TestError{name: t.Name, message: "invalid number of columns"}
 					}
-					if err := rows.Scan(actual...); err != nil {
+					actual, err := scanRowValues(rows, len(cols))
+					if err != nil {
 						return err
 					}
-					for i, val := range expected {
-						a := actual[i]
-						v1 := reflect.ValueOf(a)
-						v2 := reflect.ValueOf(val)
-						if v1.Kind() == reflect.Pointer && v2.Kind() != reflect.Pointer {
-							v1 = v1.Elem()
-							a = v1.Interface()
-						}
-						if v1.Type() != v2.Type() {
-							if v1.Type().ConvertibleTo(v2.Type()) {
-								a = v1.Convert(v2.Type()).Interface()
-							} else {
-								return &TestError{name: t.Name, message: fmt.Sprintf("incompatible types of field '%s'", cols[i].Name())}
-							}
-						}
-						if !reflect.DeepEqual(val, a) {
-							return &TestError{name: t.Name, message: fmt.Sprintf("values of field '%s' not equal: '%v' /= '%v'", cols[i].Name(), val, a)}
+					for i := range row.Columns {
+						if err := compareObject(t.Name, cols[i].Name(), &row.Columns[i], actual[i]); err != nil {
+							return err
 						}
 					}
 				}
@@ -466,16 +642,41 @@ func (t *DBTest) Run(ctx context.Context, db *sql.DB, service interface{}) error
 					return &TestError{name: t.Name, message: "more rows expected"}
 				}
 			default:
+				if len(ass.Values) > 0 {
+					if len(ass.Values) != len(r)-1 {
+						return newReturnError(t.Name, r, fmt.Sprintf("invalid number of return values of method '%s'", t.Act.Method))
+					}
+					for i, obj := range ass.Values {
+						obj := obj
+						expected, err := BuildObject(&obj)
+						if err != nil {
+							return err
+						}
+						actual := r[i].Interface()
+						eq, err := valueEquals(&obj, expected, actual)
+						if err != nil {
+							return err
+						}
+						if !eq {
+							return newReturnError(t.Name, r, fmt.Sprintf("return value %d not equal: '%v' /= '%v'", i, expected, actual))
+						}
+					}
+					break
+				}
 				if len(r) != 2 {
-					return &TestError{name: t.Name, message: fmt.Sprintf("invalid number of return values of method '%s'", t.Act.Method)}
+					return newReturnError(t.Name, r, fmt.Sprintf("invalid number of return values of method '%s'", t.Act.Method))
 				}
 				expected, err := BuildObject(&ass.Value)
 				if err != nil {
 					return err
 				}
 				actual := r[0].Interface()
-				if !reflect.DeepEqual(expected, actual) {
-					return &TestError{name: t.Name, message: fmt.Sprintf("return values not equal: '%v' /= '%v'", expected, actual)}
+				eq, err := valueEquals(&ass.Value, expected, actual)
+				if err != nil {
+					return err
+				}
+				if !eq {
+					return newReturnError(t.Name, r, fmt.Sprintf("return values not equal: '%v' /= '%v'", expected, actual))
 				}
 			}
 			return nil
@@ -484,6 +685,40 @@ func (t *DBTest) Run(ctx context.Context, db *sql.DB, service interface{}) error
 	return nil
 }
 
+// compareValue compares an expected value (built via BuildObject) against an
+// actual value scanned from a row, converting actual to expected's type where
+// possible, and returns a *TestError describing any mismatch.
+func compareValue(testName, fieldName string, expected, actual interface{}) error {
+	v1 := reflect.ValueOf(actual)
+	v2 := reflect.ValueOf(expected)
+	if v1.Kind() == reflect.Pointer && v2.Kind() != reflect.Pointer {
+		v1 = v1.Elem()
+		actual = v1.Interface()
+	}
+	if v1.Type() != v2.Type() {
+		if v1.Type().ConvertibleTo(v2.Type()) {
+			actual = v1.Convert(v2.Type()).Interface()
+		} else {
+			return &TestError{name: testName, message: fmt.Sprintf("incompatible types of field '%s'", fieldName)}
+		}
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		return &TestError{name: testName, message: fmt.Sprintf("values of field '%s' not equal: '%v' /= '%v'", fieldName, expected, actual)}
+	}
+	return nil
+}
+
+// newReturnError builds a *TestError for a return-value assertion failure,
+// populating Data with each return value keyed by its index (as a string) so
+// downstream tooling can inspect the actual values of a failed call.
+func newReturnError(testName string, r []reflect.Value, message string) *TestError {
+	data := make(map[string]interface{}, len(r))
+	for i, v := range r {
+		data[strconv.Itoa(i)] = v.Interface()
+	}
+	return &TestError{name: testName, message: message, data: data}
+}
+
 // TestError is a test error.
 type TestError struct {
 	name    string
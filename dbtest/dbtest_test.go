@@ -113,6 +113,44 @@ tests:
 	a.Equal([]*AnotherStruct{{Field1: "A", Field2: 1}, {Field1: "B", Field2: 2}}, obj.(*SomeStruct).Field5)
 }
 
+func TestBindNamed(t *testing.T) {
+	a := assert.New(t)
+
+	ph := postgresDriver{}.Placeholder
+	stmt, args, err := bindNamed(
+		"SELECT * FROM users WHERE email = :user.field1 AND id IN (:ids)",
+		map[string]interface{}{
+			"user": &AnotherStruct{Field1: "abcd", Field2: 5678},
+			"ids":  []int{1, 2, 3},
+		},
+		ph,
+	)
+	a.Nil(err)
+	a.Equal("SELECT * FROM users WHERE email = $1 AND id IN ($2, $3, $4)", stmt)
+	a.Equal([]interface{}{"abcd", 1, 2, 3}, args)
+
+	_, _, err = bindNamed("SELECT * FROM users WHERE email = :missing", nil, ph)
+	a.NotNil(err)
+
+	stmt, args, err = bindNamed(
+		"INSERT INTO events (id, payload) VALUES (:id, '{}'::jsonb)",
+		map[string]interface{}{"id": 1},
+		ph,
+	)
+	a.Nil(err)
+	a.Equal("INSERT INTO events (id, payload) VALUES ($1, '{}'::jsonb)", stmt)
+	a.Equal([]interface{}{1}, args)
+
+	stmt, args, err = bindNamed(
+		"INSERT INTO logs (id, msg) VALUES (:id, 'error:invalid_input')",
+		map[string]interface{}{"id": 1},
+		ph,
+	)
+	a.Nil(err)
+	a.Equal("INSERT INTO logs (id, msg) VALUES ($1, 'error:invalid_input')", stmt)
+	a.Equal([]interface{}{1}, args)
+}
+
 type SomeStruct struct {
 	Field1 string
 	Field2 int
@@ -0,0 +1,181 @@
+package dbtest
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// resolveMethod finds the method named name on serviceType that can be
+// called with arguments of the given types. It first tries the method named
+// exactly name; if that method exists but its parameters don't accept
+// argTypes, or if there is no method named exactly name at all, it also
+// considers methods whose name is name followed by a numeric suffix (e.g.
+// "Method", "Method2"), a common convention for emulating overloads in Go,
+// returning the first whose parameters accept argTypes (see typeMatches).
+func resolveMethod(serviceType reflect.Type, name string, argTypes []reflect.Type) (reflect.Method, error) {
+	var candidates []reflect.Method
+	if m, ok := serviceType.MethodByName(name); ok {
+		candidates = append(candidates, m)
+	}
+	for i := 0; i < serviceType.NumMethod(); i++ {
+		m := serviceType.Method(i)
+		if m.Name == name {
+			continue
+		}
+		if suffix := strings.TrimPrefix(m.Name, name); suffix != m.Name && isDigits(suffix) {
+			candidates = append(candidates, m)
+		}
+	}
+	if len(candidates) == 0 {
+		return reflect.Method{}, fmt.Errorf("method '%s' not found in service", name)
+	}
+	if len(candidates) == 1 {
+		if !methodAcceptsArgs(candidates[0], argTypes) {
+			return reflect.Method{}, fmt.Errorf("method '%s' doesn't accept the given arguments", name)
+		}
+		return candidates[0], nil
+	}
+	for _, m := range candidates {
+		if methodAcceptsArgs(m, argTypes) {
+			return m, nil
+		}
+	}
+	return reflect.Method{}, fmt.Errorf("no overload of method '%s' accepts the given arguments", name)
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// methodAcceptsArgs reports whether m (a method, so m.Type.In(0) is the
+// receiver) can be called with argTypes, honoring variadic methods.
+func methodAcceptsArgs(m reflect.Method, argTypes []reflect.Type) bool {
+	fixedN, variadicElem := methodShape(m)
+	if len(argTypes) < fixedN {
+		return false
+	}
+	if variadicElem == nil && len(argTypes) != fixedN {
+		return false
+	}
+	for i := 0; i < fixedN; i++ {
+		if !typeMatches(argTypes[i], m.Type.In(i+1)) {
+			return false
+		}
+	}
+	if variadicElem == nil {
+		return true
+	}
+	rest := argTypes[fixedN:]
+	if len(rest) == 1 && rest[0] == m.Type.In(m.Type.NumIn()-1) {
+		return true
+	}
+	for _, t := range rest {
+		if !typeMatches(t, variadicElem) {
+			return false
+		}
+	}
+	return true
+}
+
+// typeMatches reports whether a built argument of type t can be passed where
+// target is expected: either directly, or via numeric widening between two
+// numeric kinds (e.g. int to int64). It deliberately doesn't fall back to the
+// much more permissive reflect.Type.ConvertibleTo, which would also accept
+// conversions such as int to string (rune conversion) and could make an
+// overload resolve against the wrong candidate.
+func typeMatches(t, target reflect.Type) bool {
+	if t.AssignableTo(target) {
+		return true
+	}
+	return isNumericKind(t.Kind()) && isNumericKind(target.Kind()) && t.ConvertibleTo(target)
+}
+
+func isNumericKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// methodShape returns the number of fixed (non-variadic) parameters of m,
+// excluding the receiver, and the variadic parameter's element type (nil if
+// m isn't variadic).
+func methodShape(m reflect.Method) (fixedN int, variadicElem reflect.Type) {
+	fixedN = m.Type.NumIn() - 1
+	if m.Type.IsVariadic() {
+		fixedN--
+		variadicElem = m.Type.In(m.Type.NumIn() - 1).Elem()
+	}
+	return fixedN, variadicElem
+}
+
+// buildCallArgs builds the reflect.Values to call m on receiver with the
+// given built arguments. If m is variadic and the last built argument is
+// already a slice matching the variadic parameter's type exactly, it is
+// passed through as-is and the caller must invoke m.Func.CallSlice; the
+// returned bool reports this. Otherwise each built argument is matched
+// positionally against m's parameters (trailing arguments against the
+// variadic element type, if any) for use with m.Func.Call, which itself
+// builds the variadic slice from the individual values.
+func buildCallArgs(m reflect.Method, receiver reflect.Value, built []interface{}) ([]reflect.Value, bool, error) {
+	fixedN, variadicElem := methodShape(m)
+	if len(built) < fixedN {
+		return nil, false, fmt.Errorf("too few arguments to method '%s'", m.Name)
+	}
+	if variadicElem == nil && len(built) != fixedN {
+		return nil, false, fmt.Errorf("invalid number of arguments to method '%s'", m.Name)
+	}
+
+	args := make([]reflect.Value, 0, len(built)+1)
+	args = append(args, receiver)
+	for i := 0; i < fixedN; i++ {
+		v, err := convertArg(built[i], m.Type.In(i+1))
+		if err != nil {
+			return nil, false, fmt.Errorf("argument %d of method '%s': %w", i, m.Name, err)
+		}
+		args = append(args, v)
+	}
+	if variadicElem == nil {
+		return args, false, nil
+	}
+
+	rest := built[fixedN:]
+	variadicType := m.Type.In(m.Type.NumIn() - 1)
+	if len(rest) == 1 {
+		if v := reflect.ValueOf(rest[0]); v.Type() == variadicType {
+			return append(args, v), true, nil
+		}
+	}
+	for _, b := range rest {
+		v, err := convertArg(b, variadicElem)
+		if err != nil {
+			return nil, false, fmt.Errorf("variadic argument of method '%s': %w", m.Name, err)
+		}
+		args = append(args, v)
+	}
+	return args, false, nil
+}
+
+func convertArg(built interface{}, target reflect.Type) (reflect.Value, error) {
+	v := reflect.ValueOf(built)
+	if v.Type().AssignableTo(target) {
+		return v, nil
+	}
+	if v.Type().ConvertibleTo(target) {
+		return v.Convert(target), nil
+	}
+	return reflect.Value{}, fmt.Errorf("type mismatch, got '%s', want '%s'", v.Type(), target)
+}
@@ -0,0 +1,84 @@
+package dbtest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type dispatchService struct{}
+
+func (dispatchService) Sum(nums []int) int {
+	var total int
+	for _, n := range nums {
+		total += n
+	}
+	return total
+}
+
+func (dispatchService) Join(sep string, parts ...string) string {
+	out := parts[0]
+	for _, p := range parts[1:] {
+		out += sep + p
+	}
+	return out
+}
+
+func (dispatchService) Greet(name string) string {
+	return "hello, " + name
+}
+
+func TestResolveMethod(t *testing.T) {
+	a := assert.New(t)
+
+	st := reflect.TypeOf(dispatchService{})
+
+	m, err := resolveMethod(st, "Sum", []reflect.Type{reflect.TypeOf([]int{})})
+	a.Nil(err)
+	a.Equal("Sum", m.Name)
+
+	_, err = resolveMethod(st, "Missing", nil)
+	a.NotNil(err)
+}
+
+func TestTypeMatches(t *testing.T) {
+	a := assert.New(t)
+
+	a.True(typeMatches(reflect.TypeOf(1), reflect.TypeOf(int64(0))))
+	a.True(typeMatches(reflect.TypeOf("s"), reflect.TypeOf("")))
+	a.False(typeMatches(reflect.TypeOf(1), reflect.TypeOf("")))
+}
+
+func TestResolveMethodRejectsConvertibleButMismatchedArgs(t *testing.T) {
+	a := assert.New(t)
+
+	st := reflect.TypeOf(dispatchService{})
+
+	// int is Go-convertible to string (rune conversion), but that's not a
+	// sane match for a method expecting a string argument.
+	_, err := resolveMethod(st, "Greet", []reflect.Type{reflect.TypeOf(65)})
+	a.NotNil(err)
+}
+
+func TestBuildCallArgsVariadic(t *testing.T) {
+	a := assert.New(t)
+
+	st := reflect.TypeOf(dispatchService{})
+	m, _ := st.MethodByName("Join")
+	receiver := reflect.ValueOf(dispatchService{})
+
+	// Individual variadic elements use plain Call.
+	args, callSlice, err := buildCallArgs(m, receiver, []interface{}{"-", "a", "b", "c"})
+	a.Nil(err)
+	a.False(callSlice)
+	r := m.Func.Call(args)
+	a.Equal("a-b-c", r[0].String())
+
+	// A pre-built slice matching the variadic type uses CallSlice.
+	args, callSlice, err = buildCallArgs(m, receiver, []interface{}{"-", []string{"a", "b", "c"}})
+	a.Nil(err)
+	a.True(callSlice)
+	r = m.Func.CallSlice(args)
+	a.Equal("a-b-c", r[0].String())
+}
@@ -0,0 +1,63 @@
+package dbtest
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	_ "github.com/go-sql-driver/mysql" // MySQL driver
+	_ "github.com/lib/pq"              // Postgres driver
+	_ "github.com/mattn/go-sqlite3"    // SQLite driver
+)
+
+// Driver describes a SQL backend that a TestSet can run against.
+type Driver interface {
+	// Name is the identifier used to select this driver from a TestSet's
+	// top-level 'driver' YAML key, e.g. "postgres".
+	Name() string
+	// DriverName is the name registered with database/sql, e.g. "postgres".
+	DriverName() string
+	// Placeholder returns the driver's positional placeholder for the i'th
+	// (1-based) bound argument, e.g. "$1" for Postgres or "?" for MySQL/SQLite.
+	Placeholder(i int) string
+}
+
+// SchemaResetter is implemented by a Driver that can reset the database
+// schema to a known state before a TestSet runs.
+type SchemaResetter interface {
+	ResetSchema(ctx context.Context, db *sql.DB) error
+}
+
+var drivers = make(map[string]Driver)
+
+// RegisterDriver makes a Driver available for selection by name from a
+// TestSet's 'driver' YAML key. It is typically called from an init function.
+func RegisterDriver(d Driver) {
+	drivers[d.Name()] = d
+}
+
+func init() {
+	RegisterDriver(postgresDriver{})
+	RegisterDriver(mysqlDriver{})
+	RegisterDriver(sqlite3Driver{})
+}
+
+type postgresDriver struct{}
+
+func (postgresDriver) Name() string       { return "postgres" }
+func (postgresDriver) DriverName() string { return "postgres" }
+func (postgresDriver) Placeholder(i int) string {
+	return fmt.Sprintf("$%d", i)
+}
+
+type mysqlDriver struct{}
+
+func (mysqlDriver) Name() string             { return "mysql" }
+func (mysqlDriver) DriverName() string       { return "mysql" }
+func (mysqlDriver) Placeholder(i int) string { return "?" }
+
+type sqlite3Driver struct{}
+
+func (sqlite3Driver) Name() string             { return "sqlite3" }
+func (sqlite3Driver) DriverName() string       { return "sqlite3" }
+func (sqlite3Driver) Placeholder(i int) string { return "?" }
@@ -0,0 +1,214 @@
+package dbtest
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDriverRegistry(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal("postgres", postgresDriver{}.Name())
+	a.Equal("postgres", postgresDriver{}.DriverName())
+	a.Equal("$3", postgresDriver{}.Placeholder(3))
+
+	a.Equal("mysql", mysqlDriver{}.Name())
+	a.Equal("?", mysqlDriver{}.Placeholder(1))
+
+	a.Equal("sqlite3", sqlite3Driver{}.Name())
+	a.Equal("?", sqlite3Driver{}.Placeholder(1))
+
+	d, ok := drivers["sqlite3"]
+	a.True(ok)
+	a.Equal("sqlite3", d.DriverName())
+}
+
+// resetRecorder wraps sqlite3Driver with a SchemaResetter that records
+// whether it ran and creates the table the tests below expect.
+type resetRecorder struct {
+	sqlite3Driver
+	reset bool
+}
+
+func (d *resetRecorder) ResetSchema(ctx context.Context, db *sql.DB) error {
+	d.reset = true
+	_, err := db.ExecContext(ctx, "CREATE TABLE widgets (id INTEGER)")
+	return err
+}
+
+func TestRunWithDBCallsSchemaResetter(t *testing.T) {
+	a := assert.New(t)
+
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "db.sqlite"))
+	a.Nil(err)
+	defer db.Close()
+
+	d := &resetRecorder{}
+	a.Nil((&TestSet{}).RunWithDB(context.Background(), db, d, struct{}{}))
+	a.True(d.reset)
+
+	_, err = db.Exec("SELECT * FROM widgets")
+	a.Nil(err)
+}
+
+// widgetService reads through its own *sql.DB, the way a real service under
+// test would, rather than through whatever connection/transaction DBTest.Run
+// uses for Arrange and Assert.
+type widgetService struct {
+	db *sql.DB
+}
+
+func (s *widgetService) CountWidgets(ctx context.Context) (int, error) {
+	var n int
+	err := s.db.QueryRowContext(ctx, "SELECT count(*) FROM widgets").Scan(&n)
+	return n, err
+}
+
+// TestDBTestRunIsolateVisibility exercises DBTest.Run's Isolate handling
+// against a service wired to its own connection to the same database. With
+// the default (Isolate unset), Arrange runs directly against the database so
+// Act observes it. With Isolate: true, Arrange runs inside a transaction that
+// Act's separate connection can't see until it commits, and since Run rolls
+// the transaction back, Act never observes the Arrange data at all; this
+// documents that known limitation of explicit isolation rather than hiding
+// it behind a silently-broken default.
+func TestDBTestRunIsolateVisibility(t *testing.T) {
+	a := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "db.sqlite")
+	db, err := sql.Open("sqlite3", path)
+	a.Nil(err)
+	defer db.Close()
+	_, err = db.Exec("CREATE TABLE widgets (id INTEGER)")
+	a.Nil(err)
+
+	svcDB, err := sql.Open("sqlite3", path)
+	a.Nil(err)
+	defer svcDB.Close()
+	svc := &widgetService{db: svcDB}
+
+	d := sqlite3Driver{}
+	base := DBTest{
+		Name:    "count widgets",
+		Arrange: []Arrange{{Statement: "INSERT INTO widgets (id) VALUES (1)"}},
+		Act:     Act{Method: "CountWidgets", Arguments: []Object{{Type: "context", Value: "background"}}},
+		Assert:  []Assert{{Value: Object{Type: "int", Value: 1}}},
+	}
+
+	// Default: Arrange runs directly against db, so Act sees it through its
+	// own connection.
+	test := base
+	a.Nil(test.Run(context.Background(), db, d, svc))
+
+	_, err = db.Exec("DELETE FROM widgets")
+	a.Nil(err)
+
+	// Explicit Isolate: true runs Arrange inside a rolled-back transaction,
+	// which Act's separate connection never observes.
+	isolate := true
+	test = base
+	test.Isolate = &isolate
+	a.NotNil(test.Run(context.Background(), db, d, svc))
+}
+
+type noopService struct{}
+
+func (noopService) NoOp(ctx context.Context) error { return nil }
+
+// TestDBTestRunAssertRowsAgainstSqlite3 runs a positional Assert.Rows check
+// against sqlite3, which reports ColumnType.ScanType() as sql.NullString /
+// sql.NullInt64 for every column regardless of nullability. Scanning has to
+// work around that rather than trust ScanType().
+func TestDBTestRunAssertRowsAgainstSqlite3(t *testing.T) {
+	a := assert.New(t)
+
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "db.sqlite"))
+	a.Nil(err)
+	defer db.Close()
+	_, err = db.Exec("CREATE TABLE users (id INTEGER, email TEXT)")
+	a.Nil(err)
+
+	test := DBTest{
+		Name:    "positional rows",
+		Arrange: []Arrange{{Statement: "INSERT INTO users (id, email) VALUES (1, 'alice@example.com')"}},
+		Act:     Act{Method: "NoOp", Arguments: []Object{{Type: "context", Value: "background"}}},
+		Assert: []Assert{{
+			Query: "SELECT id, email FROM users",
+			Rows: []Row{{Columns: []Object{
+				{Type: "int", Value: 1},
+				{Type: "string", Value: "alice@example.com"},
+			}}},
+		}},
+	}
+	a.Nil(test.Run(context.Background(), db, sqlite3Driver{}, noopService{}))
+}
+
+type eventService struct {
+	db *sql.DB
+}
+
+func (s *eventService) RecordEvent(ctx context.Context, createdAt time.Time) error {
+	_, err := s.db.ExecContext(ctx, "INSERT INTO events (created_at) VALUES (?)", createdAt)
+	return err
+}
+
+// TestDBTestRunAssertByNameUsesBuiltActArgument guards against Assert.Query
+// rebuilding a named Act.Arguments reference instead of reusing the value
+// already built for Act: rebuilding a non-deterministic codec like "time"'s
+// "now" would bind a different timestamp than the one actually passed to the
+// service under test, so the count below would come back 0.
+func TestDBTestRunAssertByNameUsesBuiltActArgument(t *testing.T) {
+	a := assert.New(t)
+
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "db.sqlite"))
+	a.Nil(err)
+	defer db.Close()
+	_, err = db.Exec("CREATE TABLE events (created_at TIMESTAMP)")
+	a.Nil(err)
+
+	svc := &eventService{db: db}
+	test := DBTest{
+		Name: "assert by name binds the exact Act argument",
+		Act: Act{Method: "RecordEvent", Arguments: []Object{
+			{Type: "context", Value: "background"},
+			{Name: "created_at", Type: "time", Value: "now"},
+		}},
+		Assert: []Assert{{
+			Query: "SELECT count(*) FROM events WHERE created_at = :created_at",
+			Rows:  []Row{{Columns: []Object{{Type: "int", Value: 1}}}},
+		}},
+	}
+	a.Nil(test.Run(context.Background(), db, sqlite3Driver{}, svc))
+}
+
+// TestDBTestRunAssertColumnsByNameAgainstSqlite3 is the same as
+// TestDBTestRunAssertRowsAgainstSqlite3 but through the 'columns:'-by-name form, which goes through assertRowByName
+// instead of the positional scan path.
+func TestDBTestRunAssertColumnsByNameAgainstSqlite3(t *testing.T) {
+	a := assert.New(t)
+
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "db.sqlite"))
+	a.Nil(err)
+	defer db.Close()
+	_, err = db.Exec("CREATE TABLE users (id INTEGER, email TEXT)")
+	a.Nil(err)
+
+	test := DBTest{
+		Name:    "named columns",
+		Arrange: []Arrange{{Statement: "INSERT INTO users (id, email) VALUES (1, 'alice@example.com')"}},
+		Act:     Act{Method: "NoOp", Arguments: []Object{{Type: "context", Value: "background"}}},
+		Assert: []Assert{{
+			Query: "SELECT id, email FROM users",
+			Rows: []Row{{ColumnsByName: map[string]Object{
+				"id":    {Type: "int", Value: 1},
+				"email": {Type: "string", Value: "alice@example.com"},
+			}}},
+		}},
+	}
+	a.Nil(test.Run(context.Background(), db, sqlite3Driver{}, noopService{}))
+}
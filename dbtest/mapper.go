@@ -0,0 +1,157 @@
+package dbtest
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// NameMapperFunc maps a struct field name to the query column name used to
+// match it when the field has no `db` tag. It mirrors sqlx's NewMapperFunc.
+type NameMapperFunc func(fieldName string) string
+
+// SnakeCaseMapper maps a field name such as "UserName" to "user_name". Runs
+// of consecutive uppercase letters are treated as a single acronym segment,
+// so "ID" maps to "id" and "HTTPStatus" maps to "http_status" rather than
+// "i_d" and "h_t_t_p_status". It is the default NameMapperFunc.
+func SnakeCaseMapper(fieldName string) string {
+	runes := []rune(fieldName)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			prevLower := i > 0 && !unicode.IsUpper(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if i > 0 && (prevLower || nextLower) {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// CamelCaseMapper maps a field name such as "UserName" to "userName".
+func CamelCaseMapper(fieldName string) string {
+	if fieldName == "" {
+		return fieldName
+	}
+	return strings.ToLower(fieldName[:1]) + fieldName[1:]
+}
+
+var nameMapper NameMapperFunc = SnakeCaseMapper
+
+// SetNameMapper overrides the NameMapperFunc used to match query result
+// columns against struct fields that have no `db` tag.
+func SetNameMapper(m NameMapperFunc) {
+	nameMapper = m
+}
+
+// columnName returns the column name f should be matched against: its `db`
+// tag if present (ignoring anything after a comma, e.g. "name,omitempty"),
+// otherwise the configured NameMapperFunc applied to the field name.
+func columnName(f reflect.StructField) string {
+	if tag, ok := f.Tag.Lookup("db"); ok {
+		if i := strings.IndexByte(tag, ','); i >= 0 {
+			tag = tag[:i]
+		}
+		return tag
+	}
+	return nameMapper(f.Name)
+}
+
+// assertRowByName compares a row named by column, matching row.ColumnsByName
+// entries against the query's result columns by name rather than position.
+func assertRowByName(testName string, row Row, cols []*sql.ColumnType, rows *sql.Rows) error {
+	actual, err := scanRowValues(rows, len(cols))
+	if err != nil {
+		return err
+	}
+	byName := make(map[string]interface{}, len(cols))
+	for i, col := range cols {
+		byName[col.Name()] = actual[i]
+	}
+	for name, obj := range row.ColumnsByName {
+		a, ok := byName[name]
+		if !ok {
+			return &TestError{name: testName, message: fmt.Sprintf("column '%s' not found in result set", name)}
+		}
+		obj := obj
+		if err := compareObject(testName, name, &obj, a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// assertResultSet implements Assert.As: it scans every row of rows into a
+// new instance of the registered struct type named by ass.As, matching
+// columns to fields by columnName, then DeepEquals the resulting slice
+// against ass.Value.
+func assertResultSet(testName string, rows *sql.Rows, ass Assert) error {
+	t, ok := customTypes[ass.As]
+	if !ok {
+		return fmt.Errorf("unknown type '%s' in 'as'", ass.As)
+	}
+
+	actual := reflect.MakeSlice(reflect.SliceOf(reflect.PointerTo(t)), 0, 0)
+	for rows.Next() {
+		inst, err := scanRowInto(rows, t)
+		if err != nil {
+			return err
+		}
+		actual = reflect.Append(actual, inst)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	expected, err := BuildObject(&ass.Value)
+	if err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(expected, actual.Interface()) {
+		return &TestError{name: testName, message: fmt.Sprintf("result set not equal: '%v' /= '%v'", expected, actual.Interface())}
+	}
+	return nil
+}
+
+// scanRowInto scans the current row of rows into a new *t, matching columns
+// to fields by columnName. Columns with no matching field are discarded.
+func scanRowInto(rows *sql.Rows, t reflect.Type) (reflect.Value, error) {
+	cols, err := rows.ColumnTypes()
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	fieldByColumn := make(map[string]int, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		fieldByColumn[columnName(t.Field(i))] = i
+	}
+
+	inst := reflect.New(t).Elem()
+	dest := make([]interface{}, len(cols))
+	field := make([]int, len(cols))
+	for i, col := range cols {
+		if fi, ok := fieldByColumn[col.Name()]; ok {
+			field[i] = fi + 1
+			dest[i] = reflect.New(inst.Field(fi).Type()).Interface()
+		} else {
+			var discard interface{}
+			dest[i] = &discard
+		}
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return reflect.Value{}, err
+	}
+	for i, fi := range field {
+		if fi == 0 {
+			continue
+		}
+		inst.Field(fi - 1).Set(reflect.ValueOf(dest[i]).Elem())
+	}
+	return inst.Addr(), nil
+}
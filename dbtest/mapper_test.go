@@ -0,0 +1,37 @@
+package dbtest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnakeCaseMapper(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal("user_name", SnakeCaseMapper("UserName"))
+	a.Equal("id", SnakeCaseMapper("ID"))
+	a.Equal("user_id", SnakeCaseMapper("UserID"))
+	a.Equal("http_status", SnakeCaseMapper("HTTPStatus"))
+	a.Equal("url", SnakeCaseMapper("URL"))
+}
+
+func TestCamelCaseMapper(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal("userName", CamelCaseMapper("UserName"))
+}
+
+func TestColumnName(t *testing.T) {
+	a := assert.New(t)
+
+	type row struct {
+		UserName string
+		Email    string `db:"email_address"`
+	}
+	typ := reflect.TypeOf(row{})
+
+	a.Equal("user_name", columnName(typ.Field(0)))
+	a.Equal("email_address", columnName(typ.Field(1)))
+}
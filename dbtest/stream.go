@@ -0,0 +1,244 @@
+package dbtest
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Stream describes a streaming/batched assertion against expected rows read
+// from an external file, for result sets too large to hold in memory via Rows.
+type Stream struct {
+	// Path is the file containing expected rows.
+	Path string `yaml:"path"`
+	// Format is the file's encoding: "yaml", "jsonl" or "csv". Defaults to a
+	// guess based on Path's extension (".jsonl", ".csv", otherwise "yaml").
+	Format string `yaml:"format"`
+	// FetchBatchSize is how many rows are scanned and compared per batch, so
+	// the whole result set never needs to be held in memory. Defaults to 1000.
+	FetchBatchSize int `yaml:"batchSize"`
+	// Checksum, if true, compares a rolling FNV-1a hash of each canonicalized
+	// row instead of comparing every value, so million-row fixtures can be
+	// verified without ever holding the full expected or actual row set.
+	Checksum bool `yaml:"checksum"`
+}
+
+func (s *Stream) batchSize() int {
+	if s.FetchBatchSize > 0 {
+		return s.FetchBatchSize
+	}
+	return 1000
+}
+
+func (s *Stream) format() string {
+	if s.Format != "" {
+		return s.Format
+	}
+	switch filepath.Ext(s.Path) {
+	case ".jsonl":
+		return "jsonl"
+	case ".csv":
+		return "csv"
+	default:
+		return "yaml"
+	}
+}
+
+// assertStream implements Assert.Stream: it scans rows in batches of
+// stream.FetchBatchSize, comparing each batch against the next rows read from
+// the external expected-rows file before discarding the batch.
+func assertStream(testName string, rows *sql.Rows, stream *Stream) error {
+	cols, err := rows.ColumnTypes()
+	if err != nil {
+		return err
+	}
+
+	src, err := openExpectedRowSource(stream)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = src.close()
+	}()
+
+	var actualHash, expectedHash hash.Hash64
+	if stream.Checksum {
+		actualHash, expectedHash = fnv.New64a(), fnv.New64a()
+	}
+
+	batchSize := stream.batchSize()
+	batch := make([][]interface{}, 0, batchSize)
+
+	flush := func() error {
+		defer func() { batch = batch[:0] }()
+		for _, actual := range batch {
+			expected, ok, err := src.next()
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return &TestError{name: testName, message: "more rows returned than expected in stream"}
+			}
+			if stream.Checksum {
+				writeCanonicalRow(actualHash, actual)
+				writeCanonicalRow(expectedHash, expected)
+				continue
+			}
+			if len(expected) != len(actual) {
+				return &TestError{name: testName, message: "invalid number of columns in stream row"}
+			}
+			for i := range actual {
+				if fmt.Sprint(actual[i]) != fmt.Sprint(expected[i]) {
+					return &TestError{name: testName, message: fmt.Sprintf("values of field '%s' not equal: '%v' /= '%v'", cols[i].Name(), expected[i], actual[i])}
+				}
+			}
+		}
+		return nil
+	}
+
+	for rows.Next() {
+		row, err := scanRowValues(rows, len(cols))
+		if err != nil {
+			return err
+		}
+		batch = append(batch, row)
+		if len(batch) == batchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+
+	if _, ok, err := src.next(); err != nil {
+		return err
+	} else if ok {
+		return &TestError{name: testName, message: "fewer rows returned than expected in stream"}
+	}
+
+	if stream.Checksum && actualHash.Sum64() != expectedHash.Sum64() {
+		return &TestError{name: testName, message: fmt.Sprintf("stream checksum mismatch: %d /= %d", expectedHash.Sum64(), actualHash.Sum64())}
+	}
+	return nil
+}
+
+// writeCanonicalRow feeds a canonical byte representation of row into h,
+// ending each value's "%v" output with an ASCII unit separator (0x1f) and
+// the row with an ASCII record separator (0x1e). Values are not escaped, so
+// a string value that itself contains these bytes is hashed as-is.
+func writeCanonicalRow(h hash.Hash64, row []interface{}) {
+	for _, v := range row {
+		fmt.Fprintf(h, "%v\x1f", v)
+	}
+	h.Write([]byte{'\x1e'})
+}
+
+// expectedRowSource reads one expected row at a time from an external file,
+// so Stream assertions never need to hold the whole file in memory.
+type expectedRowSource interface {
+	// next reads the next row, returning ok=false once the source is exhausted.
+	next() (row []interface{}, ok bool, err error)
+	close() error
+}
+
+func openExpectedRowSource(s *Stream) (expectedRowSource, error) {
+	f, err := os.Open(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	switch format := s.format(); format {
+	case "yaml":
+		return &yamlRowSource{f: f, dec: yaml.NewDecoder(f)}, nil
+	case "jsonl":
+		return &jsonlRowSource{f: f, sc: bufio.NewScanner(f)}, nil
+	case "csv":
+		return &csvRowSource{f: f, r: csv.NewReader(f)}, nil
+	default:
+		_ = f.Close()
+		return nil, fmt.Errorf("unknown stream format '%s'", format)
+	}
+}
+
+// yamlRowSource reads one row per YAML document (documents are separated by
+// a `---` line), each document being a sequence of column values.
+type yamlRowSource struct {
+	f   *os.File
+	dec *yaml.Decoder
+}
+
+func (s *yamlRowSource) next() ([]interface{}, bool, error) {
+	var row []interface{}
+	if err := s.dec.Decode(&row); err != nil {
+		if err == io.EOF {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	return row, true, nil
+}
+
+func (s *yamlRowSource) close() error { return s.f.Close() }
+
+// jsonlRowSource reads one row per line, each line a JSON array of column values.
+type jsonlRowSource struct {
+	f  *os.File
+	sc *bufio.Scanner
+}
+
+func (s *jsonlRowSource) next() ([]interface{}, bool, error) {
+	for s.sc.Scan() {
+		line := strings.TrimSpace(s.sc.Text())
+		if line == "" {
+			continue
+		}
+		var row []interface{}
+		if err := json.Unmarshal([]byte(line), &row); err != nil {
+			return nil, false, err
+		}
+		return row, true, nil
+	}
+	if err := s.sc.Err(); err != nil {
+		return nil, false, err
+	}
+	return nil, false, nil
+}
+
+func (s *jsonlRowSource) close() error { return s.f.Close() }
+
+// csvRowSource reads one row per CSV record; values stay strings.
+type csvRowSource struct {
+	f *os.File
+	r *csv.Reader
+}
+
+func (s *csvRowSource) next() ([]interface{}, bool, error) {
+	rec, err := s.r.Read()
+	if err == io.EOF {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	row := make([]interface{}, len(rec))
+	for i, v := range rec {
+		row[i] = v
+	}
+	return row, true, nil
+}
+
+func (s *csvRowSource) close() error { return s.f.Close() }
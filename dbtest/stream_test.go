@@ -0,0 +1,66 @@
+package dbtest
+
+import (
+	"database/sql"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteCanonicalRow(t *testing.T) {
+	a := assert.New(t)
+
+	h1 := fnv.New64a()
+	writeCanonicalRow(h1, []interface{}{"a", 1})
+	h2 := fnv.New64a()
+	writeCanonicalRow(h2, []interface{}{"a", 1})
+	a.Equal(h1.Sum64(), h2.Sum64())
+
+	h3 := fnv.New64a()
+	writeCanonicalRow(h3, []interface{}{"a", 2})
+	a.NotEqual(h1.Sum64(), h3.Sum64())
+}
+
+func TestStreamFormat(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal("yaml", (&Stream{Path: "rows.yaml"}).format())
+	a.Equal("jsonl", (&Stream{Path: "rows.jsonl"}).format())
+	a.Equal("csv", (&Stream{Path: "rows.csv"}).format())
+	a.Equal("csv", (&Stream{Path: "rows.yaml", Format: "csv"}).format())
+}
+
+func TestStreamBatchSize(t *testing.T) {
+	a := assert.New(t)
+
+	a.Equal(1000, (&Stream{}).batchSize())
+	a.Equal(50, (&Stream{FetchBatchSize: 50}).batchSize())
+}
+
+// TestAssertStreamAgainstSqlite3 runs assertStream against a real *sql.Rows
+// from sqlite3, which (like mysql) reports ColumnType.ScanType() as a
+// generic sql.Null* wrapper for every column regardless of nullability.
+// assertStream must scan around that to compare and hash plain values.
+func TestAssertStreamAgainstSqlite3(t *testing.T) {
+	a := assert.New(t)
+
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "db.sqlite"))
+	a.Nil(err)
+	defer db.Close()
+	_, err = db.Exec("CREATE TABLE users (id INTEGER, email TEXT)")
+	a.Nil(err)
+	_, err = db.Exec("INSERT INTO users (id, email) VALUES (1, 'alice@example.com')")
+	a.Nil(err)
+
+	fixture := filepath.Join(t.TempDir(), "rows.jsonl")
+	a.Nil(os.WriteFile(fixture, []byte(`[1, "alice@example.com"]`+"\n"), 0o644))
+
+	rows, err := db.Query("SELECT id, email FROM users")
+	a.Nil(err)
+	defer rows.Close()
+
+	a.Nil(assertStream("stream test", rows, &Stream{Path: fixture}))
+}